@@ -0,0 +1,190 @@
+// Package bench repeats a connection attempt N times, optionally in parallel, and aggregates the
+// results into per-phase latency histograms and error counts by failure kind. It backs the
+// --count/--concurrency/--interval/--warmup flags shared by the http and reachable commands.
+package bench
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FailureKind categorizes why an attempt failed, so errors can be reported grouped by the phase
+// that caused them rather than as a flat count.
+type FailureKind string
+
+const (
+	FailureDNS     FailureKind = "dns"
+	FailureConnect FailureKind = "connect"
+	FailureTLS     FailureKind = "tls"
+	FailureRead    FailureKind = "read"
+	FailureStatus  FailureKind = "status"
+	FailureOther   FailureKind = "other"
+)
+
+// FailureError wraps an error with the FailureKind it should be counted under. Attempt functions
+// that can't otherwise be classified should return the error unwrapped, which is counted as
+// FailureOther.
+type FailureError struct {
+	Kind FailureKind
+	Err  error
+}
+
+func (e *FailureError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FailureError) Unwrap() error {
+	return e.Err
+}
+
+// Phases holds the per-phase latencies observed during a single successful attempt. Fields that
+// don't apply to a given attempt (e.g. TLS for a plaintext connection) should be left zero.
+type Phases struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	TTFB    time.Duration
+	Total   time.Duration
+}
+
+// AttemptFunc performs a single dial or request and reports how long each phase took. An error
+// should be a *FailureError when the caller can identify which phase it occurred in.
+type AttemptFunc func(ctx context.Context) (Phases, error)
+
+// Options controls how many times an AttemptFunc is run and how those runs are paced.
+type Options struct {
+	// Count is the number of attempts to record results for.
+	Count int
+	// Concurrency is the number of attempts that may be in flight at once. Values less than 1 are
+	// treated as 1.
+	Concurrency int
+	// Interval is the minimum delay between dispatching successive attempts.
+	Interval time.Duration
+	// Warmup is a number of attempts to run and discard before any are recorded, to avoid
+	// penalizing the benchmark with one-time costs like connection pool warmup.
+	Warmup int
+}
+
+// Result is the aggregated outcome of every recorded attempt.
+type Result struct {
+	Attempts  int
+	Successes int
+	Total     *Histogram
+	Phases    map[string]*Histogram
+	Errors    map[FailureKind]int
+}
+
+// phaseNames lists every phase tracked in a Result, in the order they should be reported.
+var phaseNames = []string{"dns", "connect", "tls", "ttfb"}
+
+// Run executes attempt opts.Warmup times (discarding the results), then opts.Count more times,
+// recording the outcome of each into the returned Result.
+func Run(ctx context.Context, opts Options, attempt AttemptFunc) *Result {
+	for i := 0; i < opts.Warmup; i++ {
+		attempt(ctx) //nolint:errcheck // warmup attempts are intentionally discarded
+	}
+
+	result := &Result{
+		Total:  NewHistogram(),
+		Phases: make(map[string]*Histogram, len(phaseNames)),
+		Errors: make(map[FailureKind]int),
+	}
+	for _, name := range phaseNames {
+		result.Phases[name] = NewHistogram()
+	}
+
+	var mu sync.Mutex
+	record := func(phases Phases, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		result.Attempts++
+		if err != nil {
+			var failure *FailureError
+			kind := FailureOther
+			if errors.As(err, &failure) {
+				kind = failure.Kind
+			}
+			result.Errors[kind]++
+			return
+		}
+
+		result.Successes++
+		result.Total.Record(phases.Total)
+		recordIfSet(result.Phases["dns"], phases.DNS)
+		recordIfSet(result.Phases["connect"], phases.Connect)
+		recordIfSet(result.Phases["tls"], phases.TLS)
+		recordIfSet(result.Phases["ttfb"], phases.TTFB)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan struct{})
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for range jobs {
+				phases, err := attempt(ctx)
+				record(phases, err)
+			}
+		}()
+	}
+
+	for i := 0; i < opts.Count; i++ {
+		if i > 0 && opts.Interval > 0 {
+			time.Sleep(opts.Interval)
+		}
+		jobs <- struct{}{}
+	}
+	close(jobs)
+	workers.Wait()
+
+	return result
+}
+
+func recordIfSet(h *Histogram, d time.Duration) {
+	if d > 0 {
+		h.Record(d)
+	}
+}
+
+// errorKinds returns every FailureKind present in r.Errors, sorted for deterministic output.
+func (r *Result) errorKinds() []FailureKind {
+	kinds := make([]FailureKind, 0, len(r.Errors))
+	for kind := range r.Errors {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+	return kinds
+}
+
+// Summary returns a machine-readable summary of the result, suitable for a JSON output block.
+func (r *Result) Summary() map[string]any {
+	phases := make(map[string]any, len(phaseNames))
+	for _, name := range phaseNames {
+		if h := r.Phases[name]; h.Count() > 0 {
+			phases[name] = h.Summary()
+		}
+	}
+
+	errs := make(map[string]int, len(r.Errors))
+	for kind, count := range r.Errors {
+		errs[string(kind)] = count
+	}
+
+	return map[string]any{
+		"attempts":  r.Attempts,
+		"successes": r.Successes,
+		"total":     r.Total.Summary(),
+		"phases":    phases,
+		"errors":    errs,
+	}
+}
@@ -0,0 +1,28 @@
+package bench
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// DialAttempt returns an AttemptFunc that dials a single TCP connection using dial and closes it
+// immediately. Raw TCP dialing doesn't expose a separate DNS phase the way httptrace does for
+// HTTP requests, so the whole dial is reported as the Connect phase.
+func DialAttempt(dial func(ctx context.Context) (net.Conn, error)) AttemptFunc {
+	return func(ctx context.Context) (Phases, error) {
+		start := time.Now()
+
+		conn, err := dial(ctx)
+		if err != nil {
+			return Phases{}, &FailureError{Kind: FailureConnect, Err: err}
+		}
+		elapsed := time.Since(start)
+
+		if err := conn.Close(); err != nil {
+			return Phases{}, &FailureError{Kind: FailureOther, Err: err}
+		}
+
+		return Phases{Connect: elapsed, Total: elapsed}, nil
+	}
+}
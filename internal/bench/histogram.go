@@ -0,0 +1,173 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// numBuckets covers every possible microsecond value a time.Duration can hold (time.Duration is
+// an int64 number of nanoseconds, so its microsecond count fits in 63 bits).
+const numBuckets = 64
+
+// Histogram is a fixed-size, HDR-style logarithmic latency histogram. Each bucket i holds the
+// count of samples whose microsecond value has a bit length of i, i.e. samples in the range
+// [2^(i-1), 2^i). This keeps memory at O(numBuckets) regardless of how many samples are recorded,
+// at the cost of reporting percentiles as the lower bound of their bucket rather than an exact
+// value.
+type Histogram struct {
+	counts [numBuckets]uint64
+	count  uint64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+// NewHistogram returns an empty Histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{}
+}
+
+// Record adds a single latency sample to the histogram.
+func (h *Histogram) Record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	h.counts[bucketIndex(d)]++
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.sum += d
+	h.count++
+}
+
+// Count returns the number of samples recorded.
+func (h *Histogram) Count() uint64 {
+	return h.count
+}
+
+// Min returns the smallest sample recorded, or 0 if none have been.
+func (h *Histogram) Min() time.Duration {
+	return h.min
+}
+
+// Max returns the largest sample recorded, or 0 if none have been.
+func (h *Histogram) Max() time.Duration {
+	return h.max
+}
+
+// Mean returns the arithmetic mean of every sample recorded, or 0 if none have been.
+func (h *Histogram) Mean() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.count)
+}
+
+// Percentile returns the lower bound of the bucket containing the p-th percentile (0-100), or 0
+// if no samples have been recorded.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	target := uint64(math.Ceil(p / 100 * float64(h.count)))
+	if target == 0 {
+		target = 1
+	}
+
+	var cumulative uint64
+	for i, c := range h.counts {
+		cumulative += c
+		if cumulative >= target {
+			lower, _ := bucketRange(i)
+			return time.Duration(lower) * time.Microsecond
+		}
+	}
+
+	return h.max
+}
+
+// WriteASCII renders a compact bar chart of the non-empty buckets to w.
+func (h *Histogram) WriteASCII(w io.Writer) {
+	if h.count == 0 {
+		fmt.Fprintln(w, "  (no samples)")
+		return
+	}
+
+	var widest uint64
+	for _, c := range h.counts {
+		if c > widest {
+			widest = c
+		}
+	}
+
+	const barWidth = 40
+	for i, c := range h.counts {
+		if c == 0 {
+			continue
+		}
+		lower, upper := bucketRange(i)
+		bar := int(float64(c) / float64(widest) * barWidth)
+		fmt.Fprintf(w, "  %10s - %-10s |%s %d\n",
+			formatMicros(lower), formatMicros(upper), barString(bar, barWidth), c)
+	}
+}
+
+func barString(filled, width int) string {
+	b := make([]byte, width)
+	for i := range b {
+		if i < filled {
+			b[i] = '#'
+		} else {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+func formatMicros(us uint64) string {
+	return (time.Duration(us) * time.Microsecond).String()
+}
+
+// bucketIndex returns the bucket that d's microsecond value falls into.
+func bucketIndex(d time.Duration) int {
+	us := d.Microseconds()
+	if us <= 0 {
+		return 0
+	}
+
+	idx := bits.Len64(uint64(us))
+	if idx >= numBuckets {
+		idx = numBuckets - 1
+	}
+	return idx
+}
+
+// bucketRange returns the [lower, upper) microsecond bounds that bucketIndex i represents.
+func bucketRange(i int) (lower, upper uint64) {
+	if i == 0 {
+		return 0, 1
+	}
+	return 1 << (i - 1), 1 << i
+}
+
+// Summary returns a machine-readable summary of the histogram, suitable for a JSON output block.
+func (h *Histogram) Summary() map[string]any {
+	return map[string]any{
+		"count":   h.count,
+		"min_ms":  h.Min().Milliseconds(),
+		"mean_ms": h.Mean().Milliseconds(),
+		"p50_ms":  h.Percentile(50).Milliseconds(),
+		"p90_ms":  h.Percentile(90).Milliseconds(),
+		"p95_ms":  h.Percentile(95).Milliseconds(),
+		"p99_ms":  h.Percentile(99).Milliseconds(),
+		"max_ms":  h.Max().Milliseconds(),
+	}
+}
@@ -0,0 +1,110 @@
+package bench
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// HTTPAttempt returns an AttemptFunc that performs a single HTTP round trip using client,
+// building a fresh request via newRequest for each attempt. The response body is read to
+// completion and discarded, so its read time is reflected in Phases.Total. A response with a
+// status code of 400 or higher is reported as a FailureStatus error.
+func HTTPAttempt(client *http.Client, newRequest func(ctx context.Context) (*http.Request, error)) AttemptFunc {
+	return func(ctx context.Context) (Phases, error) {
+		start := time.Now()
+
+		var phases Phases
+		var dnsStart, connectStart, tlsStart time.Time
+		var dnsStarted, dnsDone, connectStarted, connectDone, tlsStarted, tlsDone bool
+
+		trace := &httptrace.ClientTrace{
+			DNSStart: func(httptrace.DNSStartInfo) {
+				dnsStarted = true
+				dnsStart = time.Now()
+			},
+			DNSDone: func(info httptrace.DNSDoneInfo) {
+				if info.Err == nil {
+					if !dnsStart.IsZero() {
+						phases.DNS = time.Since(dnsStart)
+					}
+					dnsDone = true
+				}
+			},
+			ConnectStart: func(string, string) {
+				connectStarted = true
+				connectStart = time.Now()
+			},
+			ConnectDone: func(network, addr string, err error) {
+				if err == nil {
+					if !connectStart.IsZero() {
+						phases.Connect = time.Since(connectStart)
+					}
+					connectDone = true
+				}
+			},
+			TLSHandshakeStart: func() {
+				tlsStarted = true
+				tlsStart = time.Now()
+			},
+			TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+				if err == nil {
+					if !tlsStart.IsZero() {
+						phases.TLS = time.Since(tlsStart)
+					}
+					tlsDone = true
+				}
+			},
+			GotFirstResponseByte: func() {
+				phases.TTFB = time.Since(start)
+			},
+		}
+
+		req, err := newRequest(ctx)
+		if err != nil {
+			return Phases{}, &FailureError{Kind: FailureOther, Err: err}
+		}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+		resp, err := client.Do(req)
+		if err != nil {
+			kind := classifyRoundTripError(dnsStarted, dnsDone, connectStarted, connectDone, tlsStarted, tlsDone)
+			return Phases{}, &FailureError{Kind: kind, Err: err}
+		}
+		defer resp.Body.Close()
+
+		if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+			return Phases{}, &FailureError{Kind: FailureRead, Err: err}
+		}
+
+		phases.Total = time.Since(start)
+
+		if resp.StatusCode >= 400 {
+			return Phases{}, &FailureError{Kind: FailureStatus, Err: fmt.Errorf("unexpected status: %s", resp.Status)}
+		}
+
+		return phases, nil
+	}
+}
+
+// classifyRoundTripError picks the FailureKind for a RoundTrip error based on the furthest
+// httptrace milestone reached before it occurred. A milestone that never started (e.g. DNS
+// resolution for a literal IP address) is skipped rather than blamed.
+func classifyRoundTripError(dnsStarted, dnsDone, connectStarted, connectDone, tlsStarted, tlsDone bool) FailureKind {
+	switch {
+	case dnsStarted && !dnsDone:
+		return FailureDNS
+	case connectStarted && !connectDone:
+		return FailureConnect
+	case tlsStarted && !tlsDone:
+		return FailureTLS
+	case connectDone:
+		return FailureRead
+	default:
+		return FailureOther
+	}
+}
@@ -0,0 +1,32 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteASCII renders a human-readable report of r: an attempt/success/error summary, a
+// percentile line and bar-chart histogram for the total latency, and the same for every
+// per-phase histogram that has samples.
+func (r *Result) WriteASCII(w io.Writer) {
+	fmt.Fprintf(w, "Attempts: %d  Successes: %d  Errors: %d\n", r.Attempts, r.Successes, r.Attempts-r.Successes)
+	for _, kind := range r.errorKinds() {
+		fmt.Fprintf(w, "  %-8s %d\n", kind, r.Errors[kind])
+	}
+
+	fmt.Fprintln(w)
+	writeHistogramReport(w, "total", r.Total)
+
+	for _, name := range phaseNames {
+		if h := r.Phases[name]; h.Count() > 0 {
+			fmt.Fprintln(w)
+			writeHistogramReport(w, name, h)
+		}
+	}
+}
+
+func writeHistogramReport(w io.Writer, label string, h *Histogram) {
+	fmt.Fprintf(w, "%s (n=%d): p50=%s p90=%s p95=%s p99=%s max=%s\n",
+		label, h.Count(), h.Percentile(50), h.Percentile(90), h.Percentile(95), h.Percentile(99), h.Max())
+	h.WriteASCII(w)
+}
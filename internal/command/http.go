@@ -1,26 +1,97 @@
 package command
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
 	"os"
 	"strings"
 	"time"
+
+	"github.com/cdevoogd/conndebug/internal/bench"
+	"github.com/cdevoogd/conndebug/internal/output"
+	"github.com/cdevoogd/conndebug/internal/proxy"
+	"github.com/cdevoogd/conndebug/internal/transport"
+	"github.com/smallstep/certinfo"
 )
 
-const headerDelimiter = ":"
+const (
+	headerDelimiter   = ":"
+	contentTypeHeader = "Content-Type"
+	// defaultContentType is the content type that will be set when the user included data for a
+	// body, but has not manually set a content type header.
+	defaultContentType = "text/plain"
+)
 
 type HTTP struct {
-	URL     string        `arg:"" name:"url" help:"The URL to send a request to"`
-	Method  string        `name:"method" short:"M" default:"GET" enum:"${http_methods}"`
-	Headers []string      `name:"header" short:"H" help:"The header(s) to add to the request" placeholder:"'Header: Value'" sep:"none"`
-	Timeout time.Duration `name:"timeout" short:"t" default:"0" help:"The max amount of time the request can take. A value of 0 means no timeout."`
+	URL      string        `arg:"" name:"url" help:"the URL to send a request to"`
+	Method   string        `name:"method" short:"M" default:"GET" enum:"${http_methods}" help:"the HTTP method to send the request with"`
+	Headers  []string      `name:"header" short:"H" help:"the header(s) to add to the request" placeholder:"'Header: Value'" sep:"none"`
+	Cookies  []string      `name:"cookie" short:"c" help:"the cookie(s) to add to the request" placeholder:"key=value" sep:"none"`
+	Timeout  time.Duration `name:"timeout" short:"t" default:"0" help:"the max amount of time the request can take. A value of 0 means no timeout."`
+	DataRaw  string        `name:"data" short:"d" xor:"body" help:"raw data that should be sent in the body of the request"`
+	DataFile string        `name:"data-file" xor:"body" type:"path" help:"the path to a file (or '-' for stdin) to use as the request body"`
+
+	Insecure          bool   `name:"insecure" help:"skip TLS server verification"`
+	ServerName        string `name:"server-name" help:"override the server name used to verify the server's certificate"`
+	RootCertificate   string `name:"root-cert" type:"path" help:"path to a PEM-encoded CA root certificate to trust"`
+	ClientCertificate string `name:"cert" type:"path" help:"path to a PEM-encoded client certificate to use"`
+	ClientKey         string `name:"key" type:"path" help:"path to a PEM-encoded private key to use"`
+
+	HTTPVersion string   `name:"http-version" default:"auto" enum:"auto,1.1,2,3" help:"the HTTP protocol version to use"`
+	ALPN        []string `name:"alpn" help:"the ALPN protocol(s) to offer, in preference order" sep:","`
+
+	Proxy     string `name:"proxy" help:"a proxy URL to send the request through (http, https, or socks5)"`
+	ProxyUser string `name:"proxy-user" help:"credentials to authenticate to the proxy with" placeholder:"user:pass"`
+	ProxyCA   string `name:"proxy-ca" type:"path" help:"path to a PEM-encoded CA certificate to trust for the proxy"`
+	NoProxy   bool   `name:"no-proxy" help:"do not use a proxy, even if one is configured"`
+
+	Negotiate   bool   `name:"negotiate" help:"authenticate using SPNEGO/Kerberos"`
+	BasicAuth   string `name:"basic-auth" help:"credentials to send as HTTP Basic authentication" placeholder:"user:pass"`
+	BearerToken string `name:"bearer-token" help:"a token to send as HTTP Bearer authentication"`
+
+	PrintStatus            bool   `name:"print-status" help:"print out the response status"`
+	PrintTLSState          bool   `name:"print-tls" help:"print out the response TLS information"`
+	PrintShortCertificates bool   `name:"short-certs" help:"when printing TLS info, print the short representation of the certificates"`
+	PrintHeaders           bool   `name:"print-headers" help:"print out the response headers"`
+	OutputFile             string `name:"output-file" short:"o" type:"path" help:"a file path to write the response body to"`
+	Output                 string `name:"output" default:"human" enum:"human,json,ndjson" help:"the output format to use"`
 
-	headers http.Header
+	Count       int           `name:"count" default:"1" help:"the number of times to send the request"`
+	Concurrency int           `name:"concurrency" default:"1" help:"the number of requests that may be in flight at once"`
+	Interval    time.Duration `name:"interval" default:"0" help:"the minimum delay between dispatching successive requests"`
+	Warmup      int           `name:"warmup" default:"0" help:"a number of requests to send and discard before timing begins"`
+
+	url          *url.URL
+	headers      http.Header
+	cookieJar    *cookiejar.Jar
+	transport    http.RoundTripper
+	outputFormat output.Format
 }
 
 func (cmd *HTTP) AfterApply() error {
+	parsedURL, err := url.Parse(cmd.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse URL: %w", err)
+	}
+	switch parsedURL.Scheme {
+	case "http", "https":
+	case "":
+		return fmt.Errorf("the provided url does not include a scheme (http/https): %s", cmd.URL)
+	default:
+		return fmt.Errorf("the provided url does not include a supported scheme (http/https): %s", cmd.URL)
+	}
+	cmd.url = parsedURL
+
+	if cmd.ClientCertificate != "" && cmd.ClientKey == "" || cmd.ClientCertificate == "" && cmd.ClientKey != "" {
+		return fmt.Errorf("--cert and --key must be provided together")
+	}
+
 	cmd.headers = http.Header{}
 	for _, header := range cmd.Headers {
 		parts := strings.SplitN(header, headerDelimiter, 2)
@@ -30,28 +101,168 @@ func (cmd *HTTP) AfterApply() error {
 		cmd.headers.Add(parts[0], strings.TrimSpace(parts[1]))
 	}
 
+	// If the request is going to have a body, but the user did not explicitly set a content type,
+	// then include a default content type to prevent issues with servers that expect it. A more
+	// accurate type can be included by the user using the --header/-H flag.
+	if cmd.hasBody() && cmd.headers.Get(contentTypeHeader) == "" {
+		cmd.headers.Set(contentTypeHeader, defaultContentType)
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return fmt.Errorf("error creating cookie jar: %w", err)
+	}
+	allCookies := make([]*http.Cookie, 0, len(cmd.Cookies))
+	for _, cookie := range cmd.Cookies {
+		cookies, err := http.ParseCookie(cookie)
+		if err != nil {
+			return fmt.Errorf("failed to parse cookie input %q: %w", cookie, err)
+		}
+		allCookies = append(allCookies, cookies...)
+	}
+	jar.SetCookies(cmd.url, allCookies)
+	cmd.cookieJar = jar
+
+	version, err := transport.ParseVersion(cmd.HTTPVersion)
+	if err != nil {
+		return err
+	}
+
+	rootCertPool, err := cmd.getRootCertPool()
+	if err != nil {
+		return fmt.Errorf("error getting root cert pool: %w", err)
+	}
+
+	certificates, err := cmd.getTLSCertificates()
+	if err != nil {
+		return fmt.Errorf("error loading tls certificates: %w", err)
+	}
+
+	base := &http.Transport{TLSClientConfig: &tls.Config{
+		ServerName:         cmd.ServerName,
+		InsecureSkipVerify: cmd.Insecure,
+		RootCAs:            rootCertPool,
+		Certificates:       certificates,
+	}}
+	if !cmd.NoProxy && cmd.Proxy != "" {
+		proxyUser, proxyPass := splitCredentials(cmd.ProxyUser)
+		err = proxy.Apply(base, proxy.Options{URL: cmd.Proxy, Username: proxyUser, Password: proxyPass, CACert: cmd.ProxyCA})
+		if err != nil {
+			return fmt.Errorf("error applying proxy: %w", err)
+		}
+	}
+
+	roundTripper, err := transport.New(transport.Options{Version: version, ALPN: cmd.ALPN, Base: base})
+	if err != nil {
+		return fmt.Errorf("error building transport: %w", err)
+	}
+
+	basicUser, basicPass := splitCredentials(cmd.BasicAuth)
+	cmd.transport, err = transport.WrapAuth(roundTripper, transport.AuthOptions{
+		Negotiate:     cmd.Negotiate,
+		BasicUsername: basicUser,
+		BasicPassword: basicPass,
+		Bearer:        cmd.BearerToken,
+	})
+	if err != nil {
+		return fmt.Errorf("error applying authentication: %w", err)
+	}
+
+	cmd.outputFormat, err = output.ParseFormat(cmd.Output)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// splitCredentials splits a "user:pass" string into its two parts. If there is no delimiter, the
+// whole string is treated as the username.
+func splitCredentials(credentials string) (username, password string) {
+	username, password, _ = strings.Cut(credentials, ":")
+	return username, password
+}
+
 func (cmd *HTTP) Run() error {
-	req, err := cmd.buildRequest()
+	client := &http.Client{Timeout: cmd.Timeout, Jar: cmd.cookieJar, Transport: cmd.transport}
+
+	if cmd.Count > 1 {
+		return cmd.runBenchmark(client)
+	}
+
+	start := time.Now()
+	sink := output.NewSink(os.Stderr, cmd.outputFormat, start)
+
+	body, err := cmd.openBody()
 	if err != nil {
-		return fmt.Errorf("error building request: %w", err)
+		return sink.Fail(fmt.Errorf("error opening body: %w", err), nil)
+	}
+	defer body.Close()
+
+	req, err := cmd.buildRequest(body)
+	if err != nil {
+		return sink.Fail(fmt.Errorf("error building request: %w", err), nil)
 	}
 
-	client := &http.Client{Timeout: cmd.Timeout}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("error sending request: %w", err)
+		return sink.Fail(fmt.Errorf("error sending request: %w", err), nil)
 	}
 	defer resp.Body.Close()
 
-	_, err = io.Copy(os.Stdout, resp.Body)
-	return err
+	if cmd.PrintTLSState {
+		cmd.emitTLSState(sink, resp.TLS)
+	}
+
+	if cmd.PrintStatus {
+		sink.Emit("status", map[string]any{"status": resp.Status})
+	}
+
+	if cmd.PrintHeaders {
+		if err := resp.Header.Write(os.Stderr); err != nil {
+			return sink.Fail(fmt.Errorf("failed to write response headers: %w", err), nil)
+		}
+	}
+
+	written, err := cmd.writeOutput(resp.Body)
+	if err != nil {
+		return sink.Fail(fmt.Errorf("failed to write response body: %w", err), map[string]any{"bytes": written})
+	}
+
+	return sink.Summary(map[string]any{
+		"status_code": resp.StatusCode,
+		"proto":       resp.Proto,
+		"bytes":       written,
+	})
+}
+
+func (cmd *HTTP) runBenchmark(client *http.Client) error {
+	attempt := bench.HTTPAttempt(client, func(context.Context) (*http.Request, error) {
+		body, err := cmd.openBody()
+		if err != nil {
+			return nil, fmt.Errorf("error opening body: %w", err)
+		}
+		return cmd.buildRequest(body)
+	})
+
+	result := bench.Run(context.Background(), bench.Options{
+		Count:       cmd.Count,
+		Concurrency: cmd.Concurrency,
+		Interval:    cmd.Interval,
+		Warmup:      cmd.Warmup,
+	}, attempt)
+
+	if cmd.outputFormat == output.Human {
+		result.WriteASCII(os.Stdout)
+		return nil
+	}
+
+	sink := output.NewSink(os.Stdout, cmd.outputFormat, time.Now())
+	return sink.Summary(map[string]any{"url": cmd.URL, "benchmark": result.Summary()})
 }
 
-func (cmd *HTTP) buildRequest() (*http.Request, error) {
-	req, err := http.NewRequest(cmd.Method, cmd.URL, http.NoBody)
+func (cmd *HTTP) buildRequest(body io.ReadCloser) (*http.Request, error) {
+	req, err := http.NewRequest(cmd.Method, cmd.url.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -59,3 +270,102 @@ func (cmd *HTTP) buildRequest() (*http.Request, error) {
 	req.Header = cmd.headers
 	return req, nil
 }
+
+func (cmd *HTTP) getRootCertPool() (*x509.CertPool, error) {
+	if cmd.RootCertificate == "" {
+		return x509.SystemCertPool()
+	}
+
+	rootPEM, err := os.ReadFile(cmd.RootCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(rootPEM) {
+		return nil, fmt.Errorf("no root certs were successfully parsed from %q", cmd.RootCertificate)
+	}
+
+	return pool, nil
+}
+
+func (cmd *HTTP) getTLSCertificates() ([]tls.Certificate, error) {
+	if cmd.ClientCertificate == "" || cmd.ClientKey == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cmd.ClientCertificate, cmd.ClientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return []tls.Certificate{cert}, nil
+}
+
+func (cmd *HTTP) hasBody() bool {
+	return cmd.DataRaw != "" || cmd.DataFile != ""
+}
+
+func (cmd *HTTP) openBody() (io.ReadCloser, error) {
+	switch {
+	case cmd.DataRaw != "":
+		return io.NopCloser(strings.NewReader(cmd.DataRaw)), nil
+	case cmd.DataFile == "-":
+		return os.Stdin, nil
+	case cmd.DataFile != "":
+		return os.Open(cmd.DataFile)
+	default:
+		return http.NoBody, nil
+	}
+}
+
+func (cmd *HTTP) writeOutput(response io.Reader) (int64, error) {
+	if cmd.OutputFile == "" {
+		return io.Copy(os.Stdout, response)
+	}
+
+	f, err := os.Create(cmd.OutputFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer f.Close()
+
+	return io.Copy(f, response)
+}
+
+// emitTLSState emits a tls_state event summarizing the negotiated connection. In human output
+// mode, it additionally dumps the full peer certificate chain to stderr, since certificate text
+// doesn't fit cleanly into the fields map JSON/NDJSON readers expect.
+func (cmd *HTTP) emitTLSState(sink *output.Sink, state *tls.ConnectionState) {
+	if state == nil {
+		sink.Emit("tls_state", map[string]any{"err": "no TLS connection state is available; the request was likely unencrypted (HTTP)"})
+		return
+	}
+
+	sink.Emit("tls_state", map[string]any{
+		"version":             tls.VersionName(state.Version),
+		"cipher_suite":        tls.CipherSuiteName(state.CipherSuite),
+		"negotiated_protocol": state.NegotiatedProtocol,
+		"server_name":         state.ServerName,
+		"peer_certificates":   len(state.PeerCertificates),
+	})
+
+	if cmd.outputFormat != output.Human {
+		return
+	}
+
+	getCertificateText := certinfo.CertificateText
+	if cmd.PrintShortCertificates {
+		getCertificateText = certinfo.CertificateShortText
+	}
+
+	for i, cert := range state.PeerCertificates {
+		fmt.Fprintf(os.Stderr, "Peer Certificate #%d:\n", i)
+		text, err := getCertificateText(cert)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to parse certificate: %s", err)
+			continue
+		}
+		fmt.Fprintln(os.Stderr, text)
+	}
+}
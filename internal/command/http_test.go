@@ -0,0 +1,144 @@
+package command
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kong"
+)
+
+// supportedHTTPMethods mirrors the ${http_methods} kong.Vars binding that main.go supplies to
+// the real CLI, so these tests see the same enum constraints a user would.
+var supportedHTTPMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPost, http.MethodPatch, http.MethodDelete,
+}
+
+// parseHTTP parses args against a fresh HTTP command, exercising the same kong grammar and vars
+// the real CLI uses, and returns the populated command along with any parse/validation error.
+func parseHTTP(t *testing.T, args ...string) (*HTTP, error) {
+	t.Helper()
+
+	var cli HTTP
+	parser, err := kong.New(&cli, kong.Vars{"http_methods": strings.Join(supportedHTTPMethods, ",")})
+	if err != nil {
+		t.Fatalf("failed to build kong parser: %s", err)
+	}
+
+	_, err = parser.Parse(args)
+	return &cli, err
+}
+
+func TestHTTPFlagParsing(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name: "minimal valid invocation",
+			args: []string{"http://example.com"},
+		},
+		{
+			name:    "missing url argument",
+			args:    []string{},
+			wantErr: "url",
+		},
+		{
+			name:    "url missing scheme",
+			args:    []string{"example.com"},
+			wantErr: "does not include a scheme",
+		},
+		{
+			name:    "url with unsupported scheme",
+			args:    []string{"ftp://example.com"},
+			wantErr: "does not include a supported scheme",
+		},
+		{
+			name: "method enum accepts a supported method",
+			args: []string{"--method", "POST", "http://example.com"},
+		},
+		{
+			name:    "method enum rejects an unsupported method",
+			args:    []string{"--method", "TRACE", "http://example.com"},
+			wantErr: `--method must be one of`,
+		},
+		{
+			name: "http-version enum accepts a supported value",
+			args: []string{"--http-version", "2", "http://example.com"},
+		},
+		{
+			name:    "http-version enum rejects an unsupported value",
+			args:    []string{"--http-version", "4", "http://example.com"},
+			wantErr: `--http-version must be one of`,
+		},
+		{
+			name: "output enum accepts a supported value",
+			args: []string{"--output", "ndjson", "http://example.com"},
+		},
+		{
+			name:    "output enum rejects an unsupported value",
+			args:    []string{"--output", "yaml", "http://example.com"},
+			wantErr: `--output must be one of`,
+		},
+		{
+			name:    "data and data-file are mutually exclusive",
+			args:    []string{"--data", "foo", "--data-file", "bar", "http://example.com"},
+			wantErr: "can't be used together",
+		},
+		{
+			name: "data alone is fine",
+			args: []string{"--data", "foo", "http://example.com"},
+		},
+		{
+			name: "data-file alone is fine",
+			args: []string{"--data-file", "bar", "http://example.com"},
+		},
+		{
+			name:    "cert without key is rejected",
+			args:    []string{"--cert", "cert.pem", "http://example.com"},
+			wantErr: "--cert and --key must be provided together",
+		},
+		{
+			name:    "key without cert is rejected",
+			args:    []string{"--key", "key.pem", "http://example.com"},
+			wantErr: "--cert and --key must be provided together",
+		},
+		{
+			// Providing both flags satisfies the required-together check, so the next failure
+			// encountered is loading the (nonexistent) certificate file rather than that check.
+			name:    "cert and key together pass the required-together check",
+			args:    []string{"--cert", "cert.pem", "--key", "key.pem", "http://example.com"},
+			wantErr: "error loading tls certificates",
+		},
+		{
+			name:    "malformed header is rejected",
+			args:    []string{"--header", "no-delimiter", "http://example.com"},
+			wantErr: "malformed",
+		},
+		{
+			name: "well formed header is fine",
+			args: []string{"--header", "X-Test: value", "http://example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseHTTP(t, tt.args...)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got: %s", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("expected an error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got: %s", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -1,72 +1,103 @@
 package command
 
 import (
-	"crypto/tls"
 	"fmt"
 	"net/http"
 	"net/http/httptrace"
+	"os"
 	"time"
+
+	"github.com/cdevoogd/conndebug/internal/output"
+	"github.com/cdevoogd/conndebug/internal/proxy"
+	"github.com/cdevoogd/conndebug/internal/transport"
 )
 
 type HTTPTrace struct {
-	URL string `arg:"" name:"url" help:"the URL to send a request to"`
+	URL         string   `arg:"" name:"url" help:"the URL to send a request to"`
+	HTTPVersion string   `name:"http-version" default:"auto" enum:"auto,1.1,2,3" help:"the HTTP protocol version to use"`
+	ALPN        []string `name:"alpn" help:"the ALPN protocol(s) to offer, in preference order" sep:","`
+	Proxy       string   `name:"proxy" help:"a proxy URL to send the request through (http, https, or socks5)"`
+	ProxyUser   string   `name:"proxy-user" help:"credentials to authenticate to the proxy with" placeholder:"user:pass"`
+	ProxyCA     string   `name:"proxy-ca" help:"path to a PEM-encoded CA certificate to trust for the proxy" type:"path"`
+	NoProxy     bool     `name:"no-proxy" help:"do not use a proxy, even if one is configured"`
+	Negotiate   bool     `name:"negotiate" help:"authenticate using SPNEGO/Kerberos"`
+	BasicAuth   string   `name:"basic-auth" help:"credentials to send as HTTP Basic authentication" placeholder:"user:pass"`
+	BearerToken string   `name:"bearer-token" help:"a token to send as HTTP Bearer authentication"`
+	Output      string   `name:"output" default:"human" enum:"human,json,ndjson" help:"the output format to use"`
+	TraceHooks  string   `name:"trace-hooks" default:"minimal" enum:"all,minimal" help:"which httptrace hooks to wire up"`
 }
 
 func (cmd *HTTPTrace) Run() error {
+	outputFormat, err := output.ParseFormat(cmd.Output)
+	if err != nil {
+		return err
+	}
+
 	start := time.Now()
-	trace := &httptrace.ClientTrace{
-		DNSStart: func(info httptrace.DNSStartInfo) {
-			logWithDelta(start, "DNS start - host=%q", info.Host)
-		},
-		DNSDone: func(info httptrace.DNSDoneInfo) {
-			logWithDelta(start, "DNS done - addrs=%v", info.Addrs)
-		},
-		ConnectStart: func(network, addr string) {
-			logWithDelta(start, "Connection starting - network=%q, addr=%q", network, addr)
-		},
-		ConnectDone: func(network string, addr string, err error) {
-			if err != nil {
-				logWithDelta(start, "Connection failed - network=%q, addr=%q, err=%q", network, addr, err)
-				return
-			}
-			logWithDelta(start, "Connection done - network=%q, addr=%q", network, addr)
-		},
-		TLSHandshakeStart: func() {
-			logWithDelta(start, "TLS handshake starting")
-		},
-		TLSHandshakeDone: func(cs tls.ConnectionState, err error) {
-			if err != nil {
-				logWithDelta(start, "TLS handshake failed - err=%q", err)
-				return
-			}
-			logWithDelta(start, "TLS handshake complete")
-		},
-		GotConn: func(httptrace.GotConnInfo) {
-			logWithDelta(start, "Got connection")
+	sink := output.NewSink(os.Stdout, outputFormat, start)
+
+	version, err := transport.ParseVersion(cmd.HTTPVersion)
+	if err != nil {
+		return sink.Fail(err, nil)
+	}
+
+	base := &http.Transport{}
+	if !cmd.NoProxy && cmd.Proxy != "" {
+		proxyUser, proxyPass := splitCredentials(cmd.ProxyUser)
+		err = proxy.Apply(base, proxy.Options{URL: cmd.Proxy, Username: proxyUser, Password: proxyPass, CACert: cmd.ProxyCA})
+		if err != nil {
+			return sink.Fail(fmt.Errorf("error applying proxy: %w", err), nil)
+		}
+	}
+
+	roundTripper, err := transport.New(transport.Options{
+		Version: version,
+		ALPN:    cmd.ALPN,
+		Base:    base,
+		H2FrameHook: func(event transport.H2FrameEvent) {
+			sink.Emit("h2_frame", map[string]any{
+				"direction": event.Direction,
+				"type":      event.Type,
+				"stream_id": event.StreamID,
+				"fields":    event.Fields,
+			})
 		},
-		GotFirstResponseByte: func() {
-			logWithDelta(start, "Got first response byte")
+		H3EventHook: func(event transport.H3Event) {
+			sink.Emit(event.Kind, event.Fields)
 		},
+	})
+	if err != nil {
+		return sink.Fail(fmt.Errorf("error building transport: %w", err), nil)
 	}
 
+	basicUser, basicPass := splitCredentials(cmd.BasicAuth)
+	roundTripper, err = transport.WrapAuth(roundTripper, transport.AuthOptions{
+		Negotiate:     cmd.Negotiate,
+		BasicUsername: basicUser,
+		BasicPassword: basicPass,
+		Bearer:        cmd.BearerToken,
+	})
+	if err != nil {
+		return sink.Fail(fmt.Errorf("error applying authentication: %w", err), nil)
+	}
+
+	trace := output.NewClientTrace(sink, cmd.TraceHooks == "all")
+
 	req, err := http.NewRequest(http.MethodGet, cmd.URL, http.NoBody)
 	if err != nil {
-		return fmt.Errorf("error building request: %w", err)
+		return sink.Fail(fmt.Errorf("error building request: %w", err), nil)
 	}
 	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
 
-	resp, err := http.DefaultTransport.RoundTrip(req)
+	resp, err := roundTripper.RoundTrip(req)
 	if err != nil {
-		return fmt.Errorf("error sending request: %w", err)
+		return sink.Fail(fmt.Errorf("error sending request: %w", err), nil)
 	}
 	defer resp.Body.Close()
 
-	return nil
-}
-
-func logWithDelta(start time.Time, format string, args ...any) {
-	delta := time.Since(start)
-	fmt.Printf("%6dms: ", delta.Milliseconds())
-	fmt.Printf(format, args...)
-	fmt.Println()
+	return sink.Summary(map[string]any{
+		"proto":       resp.Proto,
+		"status":      resp.Status,
+		"status_code": resp.StatusCode,
+	})
 }
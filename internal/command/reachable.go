@@ -1,13 +1,31 @@
 package command
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
+	"os"
+	"time"
+
+	"github.com/cdevoogd/conndebug/internal/bench"
+	"github.com/cdevoogd/conndebug/internal/output"
+	"github.com/cdevoogd/conndebug/internal/proxy"
 )
 
 type Reachable struct {
-	Address string `arg:"" name:"ip:port" help:"the address to connect to"`
+	Address     string        `arg:"" name:"ip:port" help:"the address to connect to"`
+	Proxy       string        `name:"proxy" help:"a proxy to dial through (http, https, or socks5)"`
+	ProxyUser   string        `name:"proxy-user" help:"credentials to authenticate to the proxy with" placeholder:"user:pass"`
+	ProxyCA     string        `name:"proxy-ca" help:"path to a PEM-encoded CA certificate to trust for the proxy" type:"path"`
+	NoProxy     bool          `name:"no-proxy" help:"do not use a proxy, even if one is configured"`
+	Output      string        `name:"output" default:"human" enum:"human,json,ndjson" help:"the output format to use"`
+	Count       int           `name:"count" default:"1" help:"the number of times to dial the address"`
+	Concurrency int           `name:"concurrency" default:"1" help:"the number of dials that may be in flight at once"`
+	Interval    time.Duration `name:"interval" default:"0" help:"the minimum delay between dispatching successive dials"`
+	Warmup      int           `name:"warmup" default:"0" help:"a number of dials to perform and discard before timing begins"`
+
+	outputFormat output.Format
 }
 
 func (cmd *Reachable) AfterApply() error {
@@ -15,28 +33,64 @@ func (cmd *Reachable) AfterApply() error {
 	if err != nil {
 		return fmt.Errorf("the provided address is invalid: %w", err)
 	}
+
+	cmd.outputFormat, err = output.ParseFormat(cmd.Output)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (cmd *Reachable) Run() error {
-	fmt.Printf("Connecting to: %s\n", cmd.Address)
+	proxyOpts := proxy.Options{CACert: cmd.ProxyCA}
+	if !cmd.NoProxy {
+		proxyOpts.URL = cmd.Proxy
+		proxyOpts.Username, proxyOpts.Password = splitCredentials(cmd.ProxyUser)
+	}
 
-	dialer := net.Dialer{}
-	conn, err := dialer.Dial("tcp", cmd.Address)
+	if cmd.Count > 1 {
+		return cmd.runBenchmark(proxyOpts)
+	}
+
+	start := time.Now()
+	sink := output.NewSink(os.Stdout, cmd.outputFormat, start)
+	sink.Emit("connecting", map[string]any{"address": cmd.Address})
+
+	conn, err := proxy.Dial(context.Background(), proxyOpts, "tcp", cmd.Address)
 	if err != nil {
-		return fmt.Errorf("error dialing address: %w", err)
+		return sink.Fail(fmt.Errorf("error dialing address: %w", err), map[string]any{"address": cmd.Address, "reachable": false})
 	}
 
-	fmt.Println("Connection succeeded")
+	sink.Emit("connected", nil)
 
 	err = conn.Close()
 	if err != nil {
-		return fmt.Errorf("error closing the connection: %w", err)
+		return sink.Fail(fmt.Errorf("error closing the connection: %w", err), map[string]any{"address": cmd.Address})
 	}
 
-	fmt.Println("Connection closed")
+	return sink.Summary(map[string]any{"address": cmd.Address, "reachable": true})
+}
 
-	return nil
+func (cmd *Reachable) runBenchmark(proxyOpts proxy.Options) error {
+	attempt := bench.DialAttempt(func(ctx context.Context) (net.Conn, error) {
+		return proxy.Dial(ctx, proxyOpts, "tcp", cmd.Address)
+	})
+
+	result := bench.Run(context.Background(), bench.Options{
+		Count:       cmd.Count,
+		Concurrency: cmd.Concurrency,
+		Interval:    cmd.Interval,
+		Warmup:      cmd.Warmup,
+	}, attempt)
+
+	if cmd.outputFormat == output.Human {
+		result.WriteASCII(os.Stdout)
+		return nil
+	}
+
+	sink := output.NewSink(os.Stdout, cmd.outputFormat, time.Now())
+	return sink.Summary(map[string]any{"address": cmd.Address, "benchmark": result.Summary()})
 }
 
 func validateAddress(addr string) error {
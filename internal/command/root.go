@@ -4,4 +4,5 @@ type Root struct {
 	HTTP      HTTP      `cmd:"" name:"http" help:"send an HTTP request"`
 	HTTPTrace HTTPTrace `cmd:"" name:"httptrace" help:"trace an HTTP GET request"`
 	Reachable Reachable `cmd:"" name:"reachable" help:"attempt to connect and immediately close a connection to test if an address is reachable"`
+	TLS       TLS       `cmd:"" name:"tls" help:"perform a TLS handshake and report on the negotiation and peer certificates"`
 }
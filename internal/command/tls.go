@@ -0,0 +1,71 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cdevoogd/conndebug/internal/tlsinspect"
+)
+
+type TLS struct {
+	Address      string        `arg:"" name:"host:port" help:"the address to connect to"`
+	ServerName   string        `name:"server-name" help:"override the server name used for SNI and certificate verification"`
+	MinVersion   string        `name:"min-version" default:"" enum:"1.0,1.1,1.2,1.3," help:"the minimum TLS version to negotiate"`
+	MaxVersion   string        `name:"max-version" default:"" enum:"1.0,1.1,1.2,1.3," help:"the maximum TLS version to negotiate"`
+	CipherSuites []string      `name:"cipher-suites" help:"restrict the offered cipher suites to this list" sep:","`
+	ALPN         []string      `name:"alpn" help:"the ALPN protocol(s) to offer, in preference order" sep:","`
+	Insecure     bool          `name:"insecure" help:"skip verification of the peer's certificate chain"`
+	Short        bool          `name:"short" help:"print the short representation of the peer's certificates"`
+	Resume       bool          `name:"resume" help:"attempt session resumption with a second handshake and report whether it was accepted"`
+	Timeout      time.Duration `name:"timeout" short:"t" default:"0" help:"the max amount of time a handshake can take. A value of 0 means no timeout."`
+
+	opts tlsinspect.Options
+}
+
+func (cmd *TLS) AfterApply() error {
+	minVersion, err := parseOptionalVersion(cmd.MinVersion)
+	if err != nil {
+		return err
+	}
+
+	maxVersion, err := parseOptionalVersion(cmd.MaxVersion)
+	if err != nil {
+		return err
+	}
+
+	cipherSuites, err := tlsinspect.ParseCipherSuites(cmd.CipherSuites)
+	if err != nil {
+		return err
+	}
+
+	cmd.opts = tlsinspect.Options{
+		Address:            cmd.Address,
+		ServerName:         cmd.ServerName,
+		MinVersion:         minVersion,
+		MaxVersion:         maxVersion,
+		CipherSuites:       cipherSuites,
+		ALPN:               cmd.ALPN,
+		InsecureSkipVerify: cmd.Insecure,
+		Short:              cmd.Short,
+		Resume:             cmd.Resume,
+		Timeout:            cmd.Timeout,
+	}
+	return nil
+}
+
+func (cmd *TLS) Run() error {
+	return tlsinspect.Inspect(os.Stdout, cmd.opts)
+}
+
+func parseOptionalVersion(version string) (uint16, error) {
+	if version == "" {
+		return 0, nil
+	}
+
+	parsed, err := tlsinspect.ParseVersion(version)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing TLS version: %w", err)
+	}
+	return parsed, nil
+}
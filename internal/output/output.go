@@ -0,0 +1,129 @@
+// Package output renders a stream of named events, either as human-readable log lines or as
+// machine-readable JSON, so command output can be piped into tools like jq or a metrics
+// collector. It backs --output across the http, httptrace, and reachable commands.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Format selects how a Sink renders events.
+type Format string
+
+const (
+	// Human prints one log line per event, matching the tool's original output style.
+	Human Format = "human"
+	// JSON buffers every event and writes a single summary document when Summary is called.
+	JSON Format = "json"
+	// NDJSON writes one JSON object per event, streamed as they're emitted.
+	NDJSON Format = "ndjson"
+)
+
+// ParseFormat converts a user-facing --output value into a Format.
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case Human, JSON, NDJSON:
+		return Format(format), nil
+	default:
+		return "", fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// Event is a single point-in-time occurrence, such as a trace hook firing or a request
+// completing.
+type Event struct {
+	Timestamp time.Time      `json:"ts"`
+	ElapsedMS int64          `json:"elapsed_ms"`
+	Kind      string         `json:"kind"`
+	Fields    map[string]any `json:"fields,omitempty"`
+}
+
+// Sink receives events as they occur and renders them according to its Format.
+type Sink struct {
+	w      io.Writer
+	format Format
+	start  time.Time
+	events []Event
+}
+
+// NewSink creates a Sink that renders events to w, measuring elapsed time from start.
+func NewSink(w io.Writer, format Format, start time.Time) *Sink {
+	return &Sink{w: w, format: format, start: start}
+}
+
+// Emit records an event of the given kind with the given fields.
+func (s *Sink) Emit(kind string, fields map[string]any) {
+	event := s.newEvent(kind, fields)
+	switch s.format {
+	case NDJSON:
+		s.writeLine(event)
+	case JSON:
+		s.events = append(s.events, event)
+	default:
+		s.writeHuman(event)
+	}
+}
+
+// Summary records the final "summary" event and, in JSON mode, flushes every buffered event as a
+// single document. Call it exactly once, after the last Emit call.
+func (s *Sink) Summary(fields map[string]any) error {
+	event := s.newEvent("summary", fields)
+	switch s.format {
+	case NDJSON:
+		return s.writeLine(event)
+	case JSON:
+		s.events = append(s.events, event)
+		return json.NewEncoder(s.w).Encode(struct {
+			Events []Event `json:"events"`
+		}{s.events})
+	default:
+		s.writeHuman(event)
+		return nil
+	}
+}
+
+// Fail records a "summary" event describing a failure (an "error" field holding err's message,
+// merged with any extra fields) and returns err unchanged, so callers can report a failure
+// through the Sink and still propagate it as the command's result.
+func (s *Sink) Fail(err error, fields map[string]any) error {
+	failFields := map[string]any{"error": err.Error()}
+	for key, value := range fields {
+		failFields[key] = value
+	}
+	s.Summary(failFields)
+	return err
+}
+
+func (s *Sink) newEvent(kind string, fields map[string]any) Event {
+	return Event{
+		Timestamp: time.Now(),
+		ElapsedMS: time.Since(s.start).Milliseconds(),
+		Kind:      kind,
+		Fields:    fields,
+	}
+}
+
+func (s *Sink) writeLine(event Event) error {
+	return json.NewEncoder(s.w).Encode(event)
+}
+
+func (s *Sink) writeHuman(event Event) {
+	fmt.Fprintf(s.w, "%6dms: %s", event.ElapsedMS, event.Kind)
+	for _, key := range sortedKeys(event.Fields) {
+		fmt.Fprintf(s.w, " - %s=%v", key, event.Fields[key])
+	}
+	fmt.Fprintln(s.w)
+}
+
+func sortedKeys(fields map[string]any) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
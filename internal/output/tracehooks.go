@@ -0,0 +1,67 @@
+package output
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+)
+
+// NewClientTrace builds an httptrace.ClientTrace that emits an event to sink for each hook it
+// fires. When all is false, only the minimal set of hooks needed to see DNS, connect, TLS,
+// got-connection, and first-byte timing is wired; when true, the less commonly needed WroteHeaders/WroteRequest/
+// Wait100Continue hooks are wired too.
+func NewClientTrace(sink *Sink, all bool) *httptrace.ClientTrace {
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			sink.Emit("dns_start", map[string]any{"host": info.Host})
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			sink.Emit("dns_done", map[string]any{"addrs": info.Addrs, "err": errField(info.Err)})
+		},
+		ConnectStart: func(network, addr string) {
+			sink.Emit("connect_start", map[string]any{"network": network, "addr": addr})
+		},
+		ConnectDone: func(network, addr string, err error) {
+			sink.Emit("connect_done", map[string]any{"network": network, "addr": addr, "err": errField(err)})
+		},
+		TLSHandshakeStart: func() {
+			sink.Emit("tls_start", nil)
+		},
+		TLSHandshakeDone: func(cs tls.ConnectionState, err error) {
+			sink.Emit("tls_done", map[string]any{
+				"negotiated_protocol": cs.NegotiatedProtocol,
+				"version":             tls.VersionName(cs.Version),
+				"err":                 errField(err),
+			})
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			sink.Emit("got_conn", map[string]any{"reused": info.Reused, "idle": info.WasIdle})
+		},
+		GotFirstResponseByte: func() {
+			sink.Emit("first_byte", nil)
+		},
+	}
+
+	if all {
+		trace.WroteHeaders = func() {
+			sink.Emit("wrote_headers", nil)
+		}
+		trace.WroteRequest = func(info httptrace.WroteRequestInfo) {
+			sink.Emit("wrote_request", map[string]any{"err": errField(info.Err)})
+		}
+		trace.Wait100Continue = func() {
+			sink.Emit("wait_100_continue", nil)
+		}
+	}
+
+	return trace
+}
+
+// errField converts err into a value suitable for a Sink fields map: nil if err is nil,
+// otherwise its message. Most stdlib error types (*net.OpError, syscall.Errno, etc.) have
+// unexported fields and no MarshalJSON, so encoding them directly produces useless output.
+func errField(err error) any {
+	if err == nil {
+		return nil
+	}
+	return err.Error()
+}
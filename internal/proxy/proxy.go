@@ -0,0 +1,233 @@
+// Package proxy configures outbound connections to be made through an HTTP(S) or SOCKS5 proxy,
+// shared by the http, httptrace, and reachable commands so proxy behavior is consistent across
+// all three.
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+// Options configures how a connection should be proxied.
+type Options struct {
+	// URL is the proxy to connect through, e.g. "http://proxy:8080" or "socks5://proxy:1080".
+	// An empty URL means no proxy is used.
+	URL string
+	// Username and Password authenticate to the proxy, if it requires it.
+	Username string
+	Password string
+	// CACert is the path to a PEM-encoded CA certificate to additionally trust when connecting
+	// to an HTTPS proxy.
+	CACert string
+}
+
+// Apply configures t to dial through the proxy described by opts. It is a no-op if opts.URL is
+// empty.
+func Apply(t *http.Transport, opts Options) error {
+	if opts.URL == "" {
+		return nil
+	}
+
+	proxyURL, err := parseURL(opts)
+	if err != nil {
+		return err
+	}
+
+	switch proxyURL.Scheme {
+	case "https":
+		if opts.CACert != "" {
+			if err := trustCACert(t, opts.CACert); err != nil {
+				return fmt.Errorf("error trusting proxy CA certificate: %w", err)
+			}
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+		return nil
+	case "http":
+		t.Proxy = http.ProxyURL(proxyURL)
+		return nil
+	case "socks5", "socks5h":
+		dialer, err := socks5Dialer(proxyURL, opts)
+		if err != nil {
+			return err
+		}
+		t.DialContext = dialer.DialContext
+		return nil
+	default:
+		return fmt.Errorf("unsupported proxy scheme: %s", proxyURL.Scheme)
+	}
+}
+
+// Dial connects to addr through the proxy described by opts, falling back to a direct dial if
+// opts.URL is empty. It is used by commands, like reachable, that don't go through an
+// http.Transport.
+func Dial(ctx context.Context, opts Options, network, addr string) (net.Conn, error) {
+	if opts.URL == "" {
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	proxyURL, err := parseURL(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		dialer, err := socks5Dialer(proxyURL, opts)
+		if err != nil {
+			return nil, err
+		}
+		return dialer.DialContext(ctx, network, addr)
+	case "http", "https":
+		return dialHTTPConnect(ctx, proxyURL, addr, opts)
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %s", proxyURL.Scheme)
+	}
+}
+
+func parseURL(opts Options) (*url.URL, error) {
+	proxyURL, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing proxy URL: %w", err)
+	}
+	if opts.Username != "" {
+		proxyURL.User = url.UserPassword(opts.Username, opts.Password)
+	}
+	return proxyURL, nil
+}
+
+func socks5Dialer(proxyURL *url.URL, opts Options) (xproxy.ContextDialer, error) {
+	var auth *xproxy.Auth
+	if opts.Username != "" {
+		auth = &xproxy.Auth{User: opts.Username, Password: opts.Password}
+	}
+
+	dialer, err := xproxy.SOCKS5("tcp", proxyURL.Host, auth, xproxy.Direct)
+	if err != nil {
+		return nil, fmt.Errorf("error building SOCKS5 dialer: %w", err)
+	}
+
+	contextDialer, ok := dialer.(xproxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("SOCKS5 dialer does not support dialing with a context")
+	}
+	return contextDialer, nil
+}
+
+// dialHTTPConnect establishes a tunnel to addr through an HTTP(S) proxy using the CONNECT method.
+func dialHTTPConnect(ctx context.Context, proxyURL *url.URL, addr string, opts Options) (net.Conn, error) {
+	var tlsConfig *tls.Config
+	if proxyURL.Scheme == "https" {
+		tlsConfig = &tls.Config{}
+		if opts.CACert != "" {
+			pool, err := loadCACertPool(nil, opts.CACert)
+			if err != nil {
+				return nil, fmt.Errorf("error trusting proxy CA certificate: %w", err)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+	return DialHTTPConnect(ctx, proxyURL, addr, tlsConfig)
+}
+
+// DialHTTPConnect establishes a tunnel to addr through an HTTP(S) proxy using the CONNECT method,
+// using tlsConfig to dial the proxy itself if proxyURL is an "https" proxy (ignored otherwise). It
+// is exported so that transport.New can reuse it for the forced-HTTP/2 dial path, which builds its
+// own http2.Transport and so bypasses http.Transport's (and this package's Apply's) proxy handling
+// entirely.
+func DialHTTPConnect(ctx context.Context, proxyURL *url.URL, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+	dialAddr := proxyURL.Host
+	var conn net.Conn
+	var err error
+	if proxyURL.Scheme == "https" {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", dialAddr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", dialAddr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to proxy: %w", err)
+	}
+
+	connectReq := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		connectReq.Header.Set("Proxy-Authorization", "Basic "+basicAuth(proxyURL.User))
+	}
+
+	if err := connectReq.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error sending CONNECT request: %w", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), connectReq)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error reading CONNECT response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT failed: %s", resp.Status)
+	}
+
+	return conn, nil
+}
+
+func basicAuth(user *url.Userinfo) string {
+	password, _ := user.Password()
+	return base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+}
+
+func trustCACert(t *http.Transport, path string) error {
+	config := t.TLSClientConfig
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+
+	pool, err := loadCACertPool(config.RootCAs, path)
+	if err != nil {
+		return err
+	}
+
+	config.RootCAs = pool
+	t.TLSClientConfig = config
+	return nil
+}
+
+// loadCACertPool returns pool (or, if pool is nil, the system cert pool) with the PEM-encoded CA
+// certificate at path additionally trusted.
+func loadCACertPool(pool *x509.CertPool, path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if pool == nil {
+		var err error
+		pool, err = x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates were successfully parsed from %q", path)
+	}
+
+	return pool, nil
+}
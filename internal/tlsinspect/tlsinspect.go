@@ -0,0 +1,189 @@
+// Package tlsinspect performs a standalone TLS handshake against an address and reports
+// everything useful about the negotiation and the peer's certificate chain, without sending
+// any application-layer request. It backs the `tls` subcommand in internal/command.
+package tlsinspect
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/smallstep/certinfo"
+)
+
+// Options configures a TLS inspection handshake.
+type Options struct {
+	// Address is the host:port to connect to.
+	Address string
+	// ServerName overrides the SNI/verification name sent during the handshake.
+	ServerName string
+	// MinVersion and MaxVersion bound the negotiated TLS version. A value of 0 leaves the
+	// corresponding bound up to the Go TLS stack's default.
+	MinVersion uint16
+	MaxVersion uint16
+	// CipherSuites restricts the offered cipher suites. A nil slice offers the default set.
+	CipherSuites []uint16
+	// ALPN lists the protocols to offer via the application-layer protocol negotiation extension.
+	ALPN []string
+	// InsecureSkipVerify disables verification of the peer's certificate chain.
+	InsecureSkipVerify bool
+	// Short prints certinfo's abbreviated certificate representation instead of the full text.
+	Short bool
+	// Resume, if set, performs a second handshake reusing the session from the first and
+	// reports whether the server accepted the resumption ticket.
+	Resume bool
+	// Timeout bounds each handshake attempt. A value of 0 means no timeout.
+	Timeout time.Duration
+}
+
+// Inspect connects to opts.Address, performs a TLS handshake, and writes a human-readable
+// report of the negotiation and peer certificate chain to w.
+func Inspect(w io.Writer, opts Options) error {
+	config := buildConfig(opts)
+
+	state, err := handshake(opts, config)
+	if err != nil {
+		return fmt.Errorf("error performing handshake: %w", err)
+	}
+
+	printState(w, state, opts.Short)
+
+	if opts.Resume {
+		resumedState, err := handshake(opts, config)
+		if err != nil {
+			return fmt.Errorf("error performing resumption handshake: %w", err)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Resumption Attempt:")
+		fmt.Fprintln(w, "  Session Resumed:", resumedState.DidResume)
+	}
+
+	return nil
+}
+
+func buildConfig(opts Options) *tls.Config {
+	config := &tls.Config{
+		ServerName:         opts.ServerName,
+		MinVersion:         opts.MinVersion,
+		MaxVersion:         opts.MaxVersion,
+		CipherSuites:       opts.CipherSuites,
+		NextProtos:         opts.ALPN,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+	if opts.Resume {
+		config.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+	}
+	return config
+}
+
+func handshake(opts Options, config *tls.Config) (*tls.ConnectionState, error) {
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = time.Now().Add(opts.Timeout)
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.Dial("tcp", opts.Address)
+	if err != nil {
+		return nil, fmt.Errorf("error dialing address: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("error setting handshake deadline: %w", err)
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	defer tlsConn.Close()
+
+	if opts.Resume {
+		drainSessionTickets(tlsConn)
+	}
+
+	state := tlsConn.ConnectionState()
+	return &state, nil
+}
+
+// drainSessionTickets reads from conn just long enough to receive any post-handshake
+// NewSessionTicket messages. TLS 1.3 servers send session tickets after the handshake
+// completes, and crypto/tls only populates the ClientSessionCache once the client reads one off
+// the wire, so without this a --resume handshake against a TLS 1.3 server would never resume.
+func drainSessionTickets(conn *tls.Conn) {
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _ = conn.Read(make([]byte, 1))
+	_ = conn.SetReadDeadline(time.Time{})
+}
+
+func printState(w io.Writer, state *tls.ConnectionState, short bool) {
+	fmt.Fprintln(w, "TLS Version:", tls.VersionName(state.Version))
+	fmt.Fprintln(w, "Cipher Suite:", tls.CipherSuiteName(state.CipherSuite))
+	fmt.Fprintln(w, "Negotiated Protocol (ALPN):", state.NegotiatedProtocol)
+	fmt.Fprintln(w, "Server Name:", state.ServerName)
+	fmt.Fprintln(w, "OCSP Stapled:", len(state.OCSPResponse) > 0)
+	fmt.Fprintln(w, "Signed Certificate Timestamps:", len(state.SignedCertificateTimestamps))
+
+	getCertificateText := certinfo.CertificateText
+	if short {
+		getCertificateText = certinfo.CertificateShortText
+	}
+
+	for i, cert := range state.PeerCertificates {
+		fmt.Fprintf(w, "Peer Certificate #%d:\n", i)
+		text, err := getCertificateText(cert)
+		if err != nil {
+			fmt.Fprintf(w, "Failed to parse certificate: %s\n", err)
+			continue
+		}
+		fmt.Fprintln(w, text)
+	}
+}
+
+// ParseVersion converts a user-facing TLS version string (e.g. "1.2") into the corresponding
+// crypto/tls version constant.
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version: %s", version)
+	}
+}
+
+// ParseCipherSuites resolves a list of cipher suite names (as reported by tls.CipherSuiteName)
+// into their IDs, searching both the secure and insecure suite lists.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	all := append(tls.CipherSuites(), tls.InsecureCipherSuites()...)
+	suites := make([]uint16, 0, len(names))
+	for _, name := range names {
+		suite, err := findCipherSuite(all, name)
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+func findCipherSuite(all []*tls.CipherSuite, name string) (uint16, error) {
+	for _, suite := range all {
+		if suite.Name == name {
+			return suite.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown cipher suite: %s", name)
+}
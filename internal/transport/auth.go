@@ -0,0 +1,61 @@
+package transport
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/dpotapov/go-spnego"
+)
+
+// AuthOptions configures credentials to attach to outgoing requests.
+type AuthOptions struct {
+	// Negotiate enables SPNEGO/Kerberos authentication. It requires the underlying RoundTripper
+	// to be the standard library's *http.Transport.
+	Negotiate bool
+	// BasicUsername and BasicPassword, if BasicUsername is non-empty, send HTTP Basic auth.
+	BasicUsername string
+	BasicPassword string
+	// Bearer, if non-empty, sends an "Authorization: Bearer <token>" header.
+	Bearer string
+}
+
+// WrapAuth wraps rt so that requests carry the credentials described by opts. If opts requests
+// no authentication, rt is returned unchanged.
+func WrapAuth(rt http.RoundTripper, opts AuthOptions) (http.RoundTripper, error) {
+	switch {
+	case opts.Negotiate:
+		base, ok := rt.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("--negotiate requires the default transport (--http-version auto or 1.1)")
+		}
+		negotiating := &spnego.Transport{}
+		negotiating.Proxy = base.Proxy
+		negotiating.DialContext = base.DialContext
+		negotiating.TLSClientConfig = base.TLSClientConfig
+		return negotiating, nil
+	case opts.BasicUsername != "":
+		return &headerRoundTripper{next: rt, value: "Basic " + basicAuthValue(opts.BasicUsername, opts.BasicPassword)}, nil
+	case opts.Bearer != "":
+		return &headerRoundTripper{next: rt, value: "Bearer " + opts.Bearer}, nil
+	default:
+		return rt, nil
+	}
+}
+
+// headerRoundTripper sets the Authorization header on a cloned request before delegating to
+// next, so the caller's original request is left untouched.
+type headerRoundTripper struct {
+	next  http.RoundTripper
+	value string
+}
+
+func (h *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", h.value)
+	return h.next.RoundTrip(req)
+}
+
+func basicAuthValue(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
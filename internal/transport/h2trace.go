@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"io"
+	"net"
+
+	"golang.org/x/net/http2"
+)
+
+// H2FrameEvent describes a single HTTP/2 frame observed on the wire, in either direction.
+type H2FrameEvent struct {
+	Direction string // "sent" or "received"
+	Type      string
+	StreamID  uint32
+	Fields    map[string]any
+}
+
+// H2FrameHook is called for every HTTP/2 frame traced on a connection.
+type H2FrameHook func(H2FrameEvent)
+
+// traceHTTP2Conn wraps conn so that every byte written or read is, in addition to being passed
+// through unmodified, parsed as HTTP/2 frames and reported to hook. This lets the httptrace
+// command surface stream opens, HEADERS/DATA frames, and GOAWAYs without needing a hook point
+// inside golang.org/x/net/http2 itself, which doesn't expose per-frame client-side callbacks.
+func traceHTTP2Conn(conn net.Conn, hook H2FrameHook) net.Conn {
+	sentR, sentW := io.Pipe()
+	recvR, recvW := io.Pipe()
+
+	go decodeHTTP2Frames("sent", sentR, hook)
+	go decodeHTTP2Frames("received", recvR, hook)
+
+	return &http2TracedConn{Conn: conn, sentTee: sentW, recvTee: recvW}
+}
+
+type http2TracedConn struct {
+	net.Conn
+	sentTee *io.PipeWriter
+	recvTee *io.PipeWriter
+}
+
+func (c *http2TracedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		_, _ = c.recvTee.Write(p[:n])
+	}
+	if err != nil {
+		c.recvTee.CloseWithError(err)
+	}
+	return n, err
+}
+
+func (c *http2TracedConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		_, _ = c.sentTee.Write(p[:n])
+	}
+	if err != nil {
+		c.sentTee.CloseWithError(err)
+	}
+	return n, err
+}
+
+func (c *http2TracedConn) Close() error {
+	c.sentTee.Close()
+	c.recvTee.Close()
+	return c.Conn.Close()
+}
+
+// decodeHTTP2Frames reads r as a stream of HTTP/2 frames and reports each one to hook until r
+// returns an error (typically because the connection closed or the tee was closed alongside it).
+// r is always closed before returning, so a framing error (e.g. a frame past the framer's default
+// max read size) can't leave the writer side of the pipe blocked forever on the traced connection.
+func decodeHTTP2Frames(direction string, r *io.PipeReader, hook H2FrameHook) {
+	if direction == "sent" {
+		// The client sends the connection preface before any frames; it isn't itself a frame, so
+		// consume and discard it before handing the stream to the framer.
+		if _, err := io.CopyN(io.Discard, r, int64(len(http2.ClientPreface))); err != nil {
+			r.CloseWithError(err)
+			return
+		}
+	}
+
+	framer := http2.NewFramer(io.Discard, r)
+	for {
+		frame, err := framer.ReadFrame()
+		if err != nil {
+			r.CloseWithError(err)
+			return
+		}
+		hook(http2FrameEvent(direction, frame))
+	}
+}
+
+func http2FrameEvent(direction string, frame http2.Frame) H2FrameEvent {
+	header := frame.Header()
+	event := H2FrameEvent{
+		Direction: direction,
+		Type:      header.Type.String(),
+		StreamID:  header.StreamID,
+		Fields:    map[string]any{},
+	}
+
+	switch f := frame.(type) {
+	case *http2.HeadersFrame:
+		event.Fields["end_stream"] = f.StreamEnded()
+	case *http2.DataFrame:
+		event.Fields["length"] = len(f.Data())
+		event.Fields["end_stream"] = f.StreamEnded()
+	case *http2.GoAwayFrame:
+		event.Fields["error_code"] = f.ErrCode.String()
+		event.Fields["last_stream_id"] = f.LastStreamID
+	case *http2.RSTStreamFrame:
+		event.Fields["error_code"] = f.ErrCode.String()
+	case *http2.SettingsFrame:
+		event.Fields["count"] = f.NumSettings()
+	}
+
+	return event
+}
@@ -0,0 +1,11 @@
+package transport
+
+// H3Event describes a QUIC/HTTP/3 connection-level event, such as 0-RTT session resumption or a
+// path change. Only emitted when the binary is built with -tags http3.
+type H3Event struct {
+	Kind   string
+	Fields map[string]any
+}
+
+// H3EventHook is called for every H3Event observed on a QUIC connection.
+type H3EventHook func(H3Event)
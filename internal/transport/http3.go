@@ -0,0 +1,62 @@
+//go:build http3
+
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/quic-go/logging"
+)
+
+func newHTTP3Transport(tlsConfig *tls.Config, hook H3EventHook) (http.RoundTripper, error) {
+	if len(tlsConfig.NextProtos) == 0 {
+		tlsConfig.NextProtos = []string{"h3"}
+	}
+
+	transport := &http3.RoundTripper{TLSClientConfig: tlsConfig}
+	if hook != nil {
+		transport.QUICConfig = &quic.Config{
+			Tracer: func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+				return newQUICConnectionTracer(hook)
+			},
+		}
+	}
+	return transport, nil
+}
+
+// newQUICConnectionTracer builds a ConnectionTracer that reports the QUIC connection events
+// relevant to diagnosing HTTP/3 behavior. quic-go doesn't expose a dedicated "path migrated"
+// callback in this version, so the closest available signals are surfaced instead: the path the
+// connection starts on, and 0-RTT session resumption via RestoredTransportParameters, which only
+// fires when the server accepts a resumed session's early data.
+func newQUICConnectionTracer(hook H3EventHook) *logging.ConnectionTracer {
+	return &logging.ConnectionTracer{
+		StartedConnection: func(local, remote net.Addr, srcConnID, destConnID logging.ConnectionID) {
+			hook(H3Event{Kind: "h3_connection_started", Fields: map[string]any{
+				"local_addr":  local.String(),
+				"remote_addr": remote.String(),
+			}})
+		},
+		NegotiatedVersion: func(chosen logging.Version, clientVersions, serverVersions []logging.Version) {
+			hook(H3Event{Kind: "h3_version_negotiated", Fields: map[string]any{"version": chosen.String()}})
+		},
+		RestoredTransportParameters: func(parameters *logging.TransportParameters) {
+			hook(H3Event{Kind: "h3_0rtt_restored"})
+		},
+		ChoseALPN: func(protocol string) {
+			hook(H3Event{Kind: "h3_alpn_chosen", Fields: map[string]any{"protocol": protocol}})
+		},
+		ClosedConnection: func(err error) {
+			fields := map[string]any{}
+			if err != nil {
+				fields["err"] = err.Error()
+			}
+			hook(H3Event{Kind: "h3_connection_closed", Fields: fields})
+		},
+	}
+}
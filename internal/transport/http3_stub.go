@@ -0,0 +1,13 @@
+//go:build !http3
+
+package transport
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+)
+
+func newHTTP3Transport(*tls.Config, H3EventHook) (http.RoundTripper, error) {
+	return nil, errors.New("HTTP/3 support was not built in; rebuild with -tags http3")
+}
@@ -0,0 +1,168 @@
+// Package transport builds an http.RoundTripper for a user-selected HTTP protocol version
+// (auto-negotiated, HTTP/1.1, HTTP/2, or HTTP/3), shared by the http and httptrace commands so
+// protocol selection behaves identically in both.
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/cdevoogd/conndebug/internal/proxy"
+	"golang.org/x/net/http2"
+)
+
+// Version identifies the HTTP protocol version a RoundTripper should use.
+type Version int
+
+const (
+	// VersionAuto lets TLS ALPN negotiation pick between HTTP/2 and HTTP/1.1.
+	VersionAuto Version = iota
+	Version1_1
+	Version2
+	Version3
+)
+
+// ParseVersion converts a user-facing --http-version value into a Version.
+func ParseVersion(version string) (Version, error) {
+	switch version {
+	case "", "auto":
+		return VersionAuto, nil
+	case "1.1":
+		return Version1_1, nil
+	case "2":
+		return Version2, nil
+	case "3":
+		return Version3, nil
+	default:
+		return 0, fmt.Errorf("unsupported HTTP version: %s", version)
+	}
+}
+
+// Options configures the RoundTripper returned by New.
+type Options struct {
+	// Version selects the HTTP protocol version to use.
+	Version Version
+	// ALPN overrides the protocols offered during the TLS handshake. When empty, a default
+	// appropriate for Version is used.
+	ALPN []string
+	// Base is the *http.Transport used as a starting point for HTTP/1.1 and auto-negotiated
+	// HTTP/2. Its TLSClientConfig is cloned and, if necessary, mutated with ALPN protocols.
+	Base *http.Transport
+	// H2FrameHook, if set, is called for every HTTP/2 frame sent or received on a connection
+	// established with HTTP/2 forced via Version2. Connections that negotiate HTTP/2 under
+	// VersionAuto are dialed by Base itself and are not traced.
+	H2FrameHook H2FrameHook
+	// H3EventHook, if set, is called for QUIC/HTTP/3 connection-level events such as 0-RTT
+	// session resumption. Only meaningful when the binary is built with -tags http3.
+	H3EventHook H3EventHook
+}
+
+// New builds an http.RoundTripper that speaks the protocol version requested in opts.
+func New(opts Options) (http.RoundTripper, error) {
+	base := opts.Base
+	if base == nil {
+		base = &http.Transport{}
+	}
+
+	tlsConfig := base.TLSClientConfig.Clone()
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if len(opts.ALPN) > 0 {
+		tlsConfig.NextProtos = opts.ALPN
+	}
+
+	switch opts.Version {
+	case Version1_1:
+		if len(tlsConfig.NextProtos) == 0 {
+			tlsConfig.NextProtos = []string{"http/1.1"}
+		}
+		base.TLSClientConfig = tlsConfig
+		base.ForceAttemptHTTP2 = false
+		return base, nil
+	case Version2:
+		if len(tlsConfig.NextProtos) == 0 {
+			tlsConfig.NextProtos = []string{"h2"}
+		}
+		h2Transport := &http2.Transport{TLSClientConfig: tlsConfig}
+		h2Transport.DialTLSContext = dialTLSContextThroughBase(base, opts.H2FrameHook)
+		return h2Transport, nil
+	case Version3:
+		return newHTTP3Transport(tlsConfig, opts.H3EventHook)
+	default:
+		base.TLSClientConfig = tlsConfig
+		h2Transport, err := http2.ConfigureTransports(base)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring HTTP/2: %w", err)
+		}
+		wireH2FrameHook(h2Transport, opts.H2FrameHook)
+		return base, nil
+	}
+}
+
+// wireH2FrameHook installs hook, if non-nil, as a frame observer on every connection t dials
+// itself. A transport returned by http2.ConfigureTransports only receives connections that its
+// *http.Transport has already dialed, so no hook fires for them.
+func wireH2FrameHook(t *http2.Transport, hook H2FrameHook) {
+	if hook == nil {
+		return
+	}
+	t.DialTLSContext = func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+		conn, err := (&tls.Dialer{Config: cfg}).DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return traceHTTP2Conn(conn, hook), nil
+	}
+}
+
+// dialTLSContextThroughBase returns a DialTLSContext for the standalone *http2.Transport used when
+// HTTP/2 is forced via --http-version 2. Unlike the auto-negotiated path, a forced http2.Transport
+// never goes through base's own dialing, so without this any proxy configured on base via
+// proxy.Apply would be silently bypassed. hook, if non-nil, additionally wraps the resulting
+// connection to report observed HTTP/2 frames.
+func dialTLSContextThroughBase(base *http.Transport, hook H2FrameHook) func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+		rawConn, err := dialThroughBase(ctx, base, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConn := tls.Client(rawConn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+
+		if hook != nil {
+			return traceHTTP2Conn(tlsConn, hook), nil
+		}
+		return tlsConn, nil
+	}
+}
+
+// dialThroughBase establishes the underlying (pre-TLS) connection to addr using whatever proxy
+// configuration proxy.Apply has already set on base (base.Proxy for HTTP(S) proxies, or
+// base.DialContext for SOCKS5), falling back to a direct dial if neither is set.
+func dialThroughBase(ctx context.Context, base *http.Transport, network, addr string) (net.Conn, error) {
+	if base.DialContext != nil {
+		return base.DialContext(ctx, network, addr)
+	}
+	if base.Proxy == nil {
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	proxyURL, err := base.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: addr}})
+	if err != nil {
+		return nil, fmt.Errorf("error determining proxy: %w", err)
+	}
+	if proxyURL == nil {
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	return proxy.DialHTTPConnect(ctx, proxyURL, addr, base.TLSClientConfig)
+}